@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/cilium-cli/defaults"
+	"github.com/cilium/cilium/cilium-cli/status"
+	ciliumClientset "github.com/cilium/cilium/pkg/k8s/client/clientset/versioned"
+)
+
+// statusParams holds the flags accepted by `cilium status`.
+type statusParams struct {
+	Output        string
+	Watch         bool
+	WatchInterval time.Duration
+	Serve         string
+}
+
+// newCmdStatus returns the `cilium status` command along with its
+// sub-commands.
+func newCmdStatus() *cobra.Command {
+	var params statusParams
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Display status of Cilium deployment",
+		Long: `Display the status of a Cilium deployment, either as a one-shot
+snapshot, continuously with --watch, or as a Prometheus /metrics endpoint
+with --serve.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runStatus(cmd.Context(), params)
+		},
+	}
+
+	cmd.Flags().StringVarP(&params.Output, "output", "o", status.OutputSummary,
+		fmt.Sprintf("Output format, one of: %s, %s, %s, %s",
+			status.OutputSummary, status.OutputJSON, status.OutputJSONStream, status.OutputPrometheus))
+	cmd.Flags().BoolVar(&params.Watch, "watch", false,
+		"Continuously watch and report status changes instead of a one-shot snapshot")
+	cmd.Flags().DurationVar(&params.WatchInterval, "watch-interval", time.Second,
+		"Minimum time between two re-issued snapshots in --watch mode")
+	cmd.Flags().StringVar(&params.Serve, "serve", "",
+		"Expose a Prometheus /metrics endpoint on this address (e.g. :9962) instead of printing to stdout")
+
+	cmd.AddCommand(newCmdStatusDiff())
+
+	return cmd
+}
+
+func runStatus(ctx context.Context, params statusParams) error {
+	client, err := newStatusK8sClient()
+	if err != nil {
+		return fmt.Errorf("unable to create Kubernetes client: %w", err)
+	}
+
+	if params.Serve != "" {
+		return status.Serve(ctx, params.Serve, func(ctx context.Context) (*status.Status, error) {
+			return collectStatus(ctx, client)
+		})
+	}
+
+	if params.Watch {
+		return watchStatus(ctx, client, params)
+	}
+
+	s, err := collectStatus(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	return printStatus(os.Stdout, params.Output, s)
+}
+
+// watchStatus renders successive Status snapshots from status.Watch until
+// ctx is cancelled.
+func watchStatus(ctx context.Context, client *statusK8sClient, params statusParams) error {
+	ch, err := status.Watch(ctx, client, client.collectPod, status.WatchOptions{MinResyncInterval: params.WatchInterval})
+	if err != nil {
+		return fmt.Errorf("unable to start watch: %w", err)
+	}
+
+	if params.Output == status.OutputJSONStream {
+		return status.WriteJSONStream(os.Stdout, ch)
+	}
+
+	var prevLines int
+	for s := range ch {
+		if params.Output == status.OutputPrometheus {
+			fmt.Fprint(os.Stdout, s.FormatPrometheus())
+			continue
+		}
+
+		rendered, lines := s.FormatWatch(prevLines)
+		prevLines = lines
+		fmt.Fprint(os.Stdout, rendered)
+	}
+
+	return nil
+}
+
+func printStatus(w *os.File, output string, s *status.Status) error {
+	switch output {
+	case status.OutputJSON, status.OutputJSONStream:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(s)
+	case status.OutputPrometheus:
+		fmt.Fprint(w, s.FormatPrometheus())
+	default:
+		fmt.Fprint(w, s.Format())
+	}
+
+	return nil
+}
+
+// collectStatus takes a single Status snapshot by running status.Watch just
+// long enough to produce one reconciled result, reusing the same informer
+// driven collection and per-pod caching logic --watch relies on.
+func collectStatus(ctx context.Context, client *statusK8sClient) (*status.Status, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch, err := status.Watch(watchCtx, client, client.collectPod, status.WatchOptions{MinResyncInterval: 0})
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case s, ok := <-ch:
+		if !ok {
+			return nil, fmt.Errorf("watch closed before producing a snapshot")
+		}
+		return s, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// statusK8sClient is the status.K8sClient implementation used by `cilium
+// status` to set up informers and, via collectPod, to exec into pods to
+// retrieve their Cilium status and endpoint list.
+type statusK8sClient struct {
+	restConfig      *rest.Config
+	clientset       kubernetes.Interface
+	ciliumClientset ciliumClientset.Interface
+	namespace       string
+}
+
+func newStatusK8sClient() (*statusK8sClient, error) {
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	cs, err := ciliumClientset.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &statusK8sClient{
+		restConfig:      config,
+		clientset:       clientset,
+		ciliumClientset: cs,
+		namespace:       defaults.Namespace,
+	}, nil
+}
+
+func (c *statusK8sClient) Clientset() kubernetes.Interface            { return c.clientset }
+func (c *statusK8sClient) CiliumClientset() ciliumClientset.Interface { return c.ciliumClientset }
+func (c *statusK8sClient) Namespace() string                          { return c.namespace }
+
+// collectPod execs into pod's Cilium container to fetch its status and
+// endpoint list, the same data a user would get running `cilium-dbg status`
+// and `cilium-dbg endpoint list` inside the pod directly.
+func (c *statusK8sClient) collectPod(ctx context.Context, deployment, pod string) (*models.StatusResponse, []*models.Endpoint, error) {
+	container := defaults.AgentContainerName
+	if deployment != defaults.AgentDaemonSetName {
+		container = deployment
+	}
+
+	statusOut, err := c.execInPod(ctx, pod, container, []string{"cilium-dbg", "status", "-o", "json"})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to retrieve cilium status: %w", err)
+	}
+
+	var r models.StatusResponse
+	if err := json.Unmarshal(statusOut, &r); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse cilium status: %w", err)
+	}
+
+	if deployment != defaults.AgentDaemonSetName {
+		return &r, nil, nil
+	}
+
+	epOut, err := c.execInPod(ctx, pod, container, []string{"cilium-dbg", "endpoint", "list", "-o", "json"})
+	if err != nil {
+		return &r, nil, fmt.Errorf("unable to retrieve cilium endpoints: %w", err)
+	}
+
+	var eps []*models.Endpoint
+	if err := json.Unmarshal(epOut, &eps); err != nil {
+		return &r, nil, fmt.Errorf("unable to parse cilium endpoints: %w", err)
+	}
+
+	return &r, eps, nil
+}
+
+// execInPod runs command inside container of pod and returns its stdout.
+func (c *statusK8sClient) execInPod(ctx context.Context, pod, container string, command []string) ([]byte, error) {
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(c.namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(c.restConfig, "POST", req.URL())
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, stderr.String())
+	}
+
+	return stdout.Bytes(), nil
+}
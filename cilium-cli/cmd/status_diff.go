@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cilium/cilium/cilium-cli/status"
+)
+
+// newCmdStatusDiff registers the `cilium status diff` sub-command, which
+// compares two status snapshots captured with `cilium status
+// --output=json` and reports the per-deployment regressions between them,
+// so it can gate an upgrade pipeline the same way a GitOps tool compares
+// desired vs live state.
+func newCmdStatusDiff() *cobra.Command {
+	var fromFile, toFile string
+	var outputJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare two status snapshots and report regressions",
+		Long: `Compare two Cilium status snapshots, typically a saved baseline
+against a freshly captured 'cilium status --output=json', and print the
+per-deployment differences between them. Exits with a non-zero status
+code if the diff contains a regression, such as a new error, a dropped
+Ready count, or a deployment that disappeared.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			prev, err := readStatusSnapshot(fromFile)
+			if err != nil {
+				return fmt.Errorf("unable to read --from snapshot: %w", err)
+			}
+
+			next, err := readStatusSnapshot(toFile)
+			if err != nil {
+				return fmt.Errorf("unable to read --to snapshot: %w", err)
+			}
+
+			diff := next.Diff(prev)
+
+			if outputJSON {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(diff); err != nil {
+					return fmt.Errorf("unable to marshal diff: %w", err)
+				}
+			} else {
+				fmt.Fprint(os.Stdout, diff.Format())
+			}
+
+			if diff.Regressed() {
+				return fmt.Errorf("status diff contains regressions")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromFile, "from", "", "Path to the baseline status JSON snapshot")
+	cmd.Flags().StringVar(&toFile, "to", "", "Path to the status JSON snapshot to compare against --from")
+	cmd.Flags().BoolVar(&outputJSON, "output-json", false, "Render the diff as JSON instead of the default colorized summary")
+	cmd.MarkFlagRequired("from")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func readStatusSnapshot(path string) (*status.Status, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s status.Status
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package status
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/cilium/cilium/api/v1/models"
+)
+
+// StatusDiff is the result of comparing two Status snapshots, e.g. the
+// current cluster against a saved baseline captured with
+// `cilium status --output=json`.
+type StatusDiff struct {
+	// PodState holds the deployments whose PodStateCount changed.
+	PodState map[string]PodStateDiff `json:"pod_state,omitempty"`
+
+	// Images holds the deployments whose set of image tags in use changed.
+	Images map[string]ImageDiff `json:"images,omitempty"`
+
+	// Subsystems holds the deployments whose set of errored/warning
+	// subsystems changed, matched by subsystem prefix so that a flapping
+	// message (same subsystem, different text) doesn't show up as both a
+	// new and a cleared entry.
+	Subsystems map[string]SubsystemDiff `json:"subsystems,omitempty"`
+
+	// EndpointsNotReady holds, per pod, the change in the number of
+	// endpoints that aren't in the ready state.
+	EndpointsNotReady map[string]int `json:"endpoints_not_ready,omitempty"`
+
+	// Disappeared lists deployments that were present in the previous
+	// snapshot but are entirely absent from the next one.
+	Disappeared []string `json:"disappeared,omitempty"`
+
+	// CollectionErrors diffs the CollectionErrors of both snapshots.
+	CollectionErrors ListDiff `json:"collection_errors,omitempty"`
+
+	// ConfigErrors diffs the ConfigErrors of both snapshots.
+	ConfigErrors ListDiff `json:"config_errors,omitempty"`
+}
+
+// PodStateDiff captures a PodStateCount transition, e.g. "Ready 3/3 -> 2/3".
+type PodStateDiff struct {
+	Prev PodStateCount `json:"prev"`
+	Next PodStateCount `json:"next"`
+}
+
+// ImageDiff captures the image tags that started or stopped being used by a
+// deployment between two snapshots.
+type ImageDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// SubsystemDiff captures the subsystems that started or stopped reporting
+// errors/warnings for a deployment between two snapshots.
+type SubsystemDiff struct {
+	NewErrors       []string `json:"new_errors,omitempty"`
+	ClearedErrors   []string `json:"cleared_errors,omitempty"`
+	NewWarnings     []string `json:"new_warnings,omitempty"`
+	ClearedWarnings []string `json:"cleared_warnings,omitempty"`
+}
+
+func (d SubsystemDiff) empty() bool {
+	return len(d.NewErrors) == 0 && len(d.ClearedErrors) == 0 &&
+		len(d.NewWarnings) == 0 && len(d.ClearedWarnings) == 0
+}
+
+// ListDiff captures the strings that were added or removed between two
+// snapshots of an unordered list, such as CollectionErrors or ConfigErrors.
+type ListDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Diff compares s against prev, a previously captured snapshot, and returns
+// the per-deployment transitions between them. It is purely a comparison of
+// the two values; neither snapshot is mutated.
+func (s *Status) Diff(prev *Status) *StatusDiff {
+	d := &StatusDiff{
+		PodState:          map[string]PodStateDiff{},
+		Images:            map[string]ImageDiff{},
+		Subsystems:        map[string]SubsystemDiff{},
+		EndpointsNotReady: map[string]int{},
+	}
+
+	for _, name := range unionKeys(prev.PodState, s.PodState) {
+		p, n := prev.PodState[name], s.PodState[name]
+		if p != n {
+			d.PodState[name] = PodStateDiff{Prev: p, Next: n}
+		}
+	}
+
+	for _, name := range unionKeys(prev.ImageCount, s.ImageCount) {
+		img := diffStringSets(slices.Collect(maps.Keys(prev.ImageCount[name])), slices.Collect(maps.Keys(s.ImageCount[name])))
+		if len(img.Added) > 0 || len(img.Removed) > 0 {
+			d.Images[name] = ImageDiff{Added: img.Added, Removed: img.Removed}
+		}
+	}
+
+	for _, deployment := range unionKeys(prev.Errors, s.Errors) {
+		sd := diffSubsystems(prev.Errors[deployment], s.Errors[deployment])
+		if !sd.empty() {
+			d.Subsystems[deployment] = sd
+		}
+	}
+
+	for _, pod := range unionKeys(prev.CiliumEndpoints, s.CiliumEndpoints) {
+		delta := notReadyEndpoints(s.CiliumEndpoints[pod]) - notReadyEndpoints(prev.CiliumEndpoints[pod])
+		if delta != 0 {
+			d.EndpointsNotReady[pod] = delta
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(prev.PodState)) {
+		if _, ok := s.PodState[name]; !ok {
+			d.Disappeared = append(d.Disappeared, name)
+		}
+	}
+
+	d.CollectionErrors = diffStringSets(errorsToStrings(prev.CollectionErrors), errorsToStrings(s.CollectionErrors))
+	d.ConfigErrors = diffStringSets(prev.ConfigErrors, s.ConfigErrors)
+
+	return d
+}
+
+// Regressed reports whether the diff represents a regression: a new error,
+// a dropped Ready count, more not-ready endpoints on a pod, a deployment
+// that disappeared, or a new collection/configuration error. It's what `cilium status diff` uses to
+// decide its exit code when run as an upgrade-pipeline gate.
+func (d *StatusDiff) Regressed() bool {
+	for _, sd := range d.Subsystems {
+		if len(sd.NewErrors) > 0 {
+			return true
+		}
+	}
+
+	for _, pd := range d.PodState {
+		if pd.Next.Ready < pd.Prev.Ready {
+			return true
+		}
+	}
+
+	for _, delta := range d.EndpointsNotReady {
+		if delta > 0 {
+			return true
+		}
+	}
+
+	return len(d.Disappeared) > 0 ||
+		len(d.CollectionErrors.Added) > 0 ||
+		len(d.ConfigErrors.Added) > 0
+}
+
+// Format renders d as a colorized, human-readable summary, using the same
+// palette Format() uses: green for added image tags, cleared problems and
+// improved counts; red for removed image tags, new errors and disappeared
+// deployments; yellow for new warnings and worsened counts.
+func (d *StatusDiff) Format() string {
+	var buf bytes.Buffer
+
+	for _, name := range slices.Sorted(maps.Keys(d.PodState)) {
+		pd := d.PodState[name]
+		color := Green
+		if pd.Next.Ready < pd.Prev.Ready {
+			color = Yellow
+		}
+		fmt.Fprintf(&buf, "%s\t%sReady %d/%d -> %d/%d%s\n", name, color, pd.Prev.Ready, pd.Prev.Desired, pd.Next.Ready, pd.Next.Desired, Reset)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(d.Images)) {
+		img := d.Images[name]
+		for _, tag := range img.Added {
+			fmt.Fprintf(&buf, "%s\t%s+%s%s\n", name, Green, tag, Reset)
+		}
+		for _, tag := range img.Removed {
+			fmt.Fprintf(&buf, "%s\t%s-%s%s\n", name, Red, tag, Reset)
+		}
+	}
+
+	for _, deployment := range slices.Sorted(maps.Keys(d.Subsystems)) {
+		sd := d.Subsystems[deployment]
+		for _, sub := range sd.NewErrors {
+			fmt.Fprintf(&buf, "%s\t%s+error: %s%s\n", deployment, Red, sub, Reset)
+		}
+		for _, sub := range sd.ClearedErrors {
+			fmt.Fprintf(&buf, "%s\t%s-error: %s%s\n", deployment, Green, sub, Reset)
+		}
+		for _, sub := range sd.NewWarnings {
+			fmt.Fprintf(&buf, "%s\t%s+warning: %s%s\n", deployment, Yellow, sub, Reset)
+		}
+		for _, sub := range sd.ClearedWarnings {
+			fmt.Fprintf(&buf, "%s\t%s-warning: %s%s\n", deployment, Green, sub, Reset)
+		}
+	}
+
+	for _, pod := range slices.Sorted(maps.Keys(d.EndpointsNotReady)) {
+		delta := d.EndpointsNotReady[pod]
+		color := Green
+		if delta > 0 {
+			color = Yellow
+		}
+		fmt.Fprintf(&buf, "%s\t%sendpoints not ready: %+d%s\n", pod, color, delta, Reset)
+	}
+
+	for _, name := range d.Disappeared {
+		fmt.Fprintf(&buf, "%s\t%sdisappeared%s\n", name, Red, Reset)
+	}
+
+	formatListDiff(&buf, "collection error", d.CollectionErrors)
+	formatListDiff(&buf, "config error", d.ConfigErrors)
+
+	return buf.String()
+}
+
+func formatListDiff(buf *bytes.Buffer, label string, ld ListDiff) {
+	for _, msg := range ld.Added {
+		fmt.Fprintf(buf, "%s\t%s+%s%s\n", label, Red, msg, Reset)
+	}
+	for _, msg := range ld.Removed {
+		fmt.Fprintf(buf, "%s\t%s-%s%s\n", label, Green, msg, Reset)
+	}
+}
+
+// unionKeys returns the sorted union of a's and b's keys.
+func unionKeys[V any](a, b map[string]V) []string {
+	set := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		set[k] = struct{}{}
+	}
+	for k := range b {
+		set[k] = struct{}{}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+// diffStringSets returns the elements of next not in prev (added) and the
+// elements of prev not in next (removed).
+func diffStringSets(prev, next []string) ListDiff {
+	prevSet := make(map[string]struct{}, len(prev))
+	for _, s := range prev {
+		prevSet[s] = struct{}{}
+	}
+	nextSet := make(map[string]struct{}, len(next))
+	for _, s := range next {
+		nextSet[s] = struct{}{}
+	}
+
+	var d ListDiff
+	for _, s := range next {
+		if _, ok := prevSet[s]; !ok {
+			d.Added = append(d.Added, s)
+		}
+	}
+	for _, s := range prev {
+		if _, ok := nextSet[s]; !ok {
+			d.Removed = append(d.Removed, s)
+		}
+	}
+	slices.Sort(d.Added)
+	slices.Sort(d.Removed)
+
+	return d
+}
+
+// subsystemSet collects the set of subsystem prefixes (e.g. "Kvstore") that
+// have at least one recorded error or warning across all of a deployment's
+// pods.
+func subsystemSet(pods ErrorCountMap, warnings bool) []string {
+	set := map[string]struct{}{}
+	for _, pod := range pods {
+		list := pod.Errors
+		if warnings {
+			list = pod.Warnings
+		}
+		for _, err := range list {
+			prefix, _, found := strings.Cut(err.Error(), ":")
+			if !found {
+				prefix = "unknown"
+			}
+			set[prefix] = struct{}{}
+		}
+	}
+	return slices.Sorted(maps.Keys(set))
+}
+
+func diffSubsystems(prev, next ErrorCountMap) SubsystemDiff {
+	errs := diffStringSets(subsystemSet(prev, false), subsystemSet(next, false))
+	warns := diffStringSets(subsystemSet(prev, true), subsystemSet(next, true))
+
+	return SubsystemDiff{
+		NewErrors:       errs.Added,
+		ClearedErrors:   errs.Removed,
+		NewWarnings:     warns.Added,
+		ClearedWarnings: warns.Removed,
+	}
+}
+
+func notReadyEndpoints(eps []*models.Endpoint) int {
+	var n int
+	for _, ep := range eps {
+		if ep != nil && ep.Status != nil && ep.Status.State != nil && *ep.Status.State != models.EndpointStateReady {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package status
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStatusDiff(t *testing.T) {
+	prev := newStatus()
+	prev.PodState["cilium"] = PodStateCount{Type: "DaemonSet", Desired: 3, Ready: 3, Available: 3}
+	prev.ImageCount["cilium"] = MapCount{"v1.16.0": 3}
+	prev.AddAggregatedError("cilium", "cilium-aaa", fmt.Errorf("Kvstore: connection refused"))
+
+	next := newStatus()
+	next.PodState["cilium"] = PodStateCount{Type: "DaemonSet", Desired: 3, Ready: 2, Available: 2}
+	next.ImageCount["cilium"] = MapCount{"v1.17.0": 2, "v1.16.0": 1}
+	next.AddAggregatedError("cilium", "cilium-bbb", fmt.Errorf("Kubernetes: unable to connect"))
+
+	diff := next.Diff(prev)
+
+	podState, ok := diff.PodState["cilium"]
+	if !ok {
+		t.Fatalf("expected a pod state diff for cilium")
+	}
+	if podState.Prev.Ready != 3 || podState.Next.Ready != 2 {
+		t.Errorf("unexpected pod state diff: %+v", podState)
+	}
+
+	img, ok := diff.Images["cilium"]
+	if !ok {
+		t.Fatalf("expected an image diff for cilium")
+	}
+	if len(img.Added) != 1 || img.Added[0] != "v1.17.0" {
+		t.Errorf("expected v1.17.0 to be added, got %v", img.Added)
+	}
+
+	sub, ok := diff.Subsystems["cilium"]
+	if !ok {
+		t.Fatalf("expected a subsystem diff for cilium")
+	}
+	if len(sub.NewErrors) != 1 || sub.NewErrors[0] != "Kubernetes" {
+		t.Errorf("expected Kubernetes to be a new error subsystem, got %v", sub.NewErrors)
+	}
+	if len(sub.ClearedErrors) != 1 || sub.ClearedErrors[0] != "Kvstore" {
+		t.Errorf("expected Kvstore to be a cleared error subsystem, got %v", sub.ClearedErrors)
+	}
+
+	if !diff.Regressed() {
+		t.Errorf("expected diff to be regressed: dropped Ready count and a new error")
+	}
+}
+
+func TestStatusDiffNoChanges(t *testing.T) {
+	prev := newStatus()
+	prev.PodState["cilium"] = PodStateCount{Type: "DaemonSet", Desired: 3, Ready: 3, Available: 3}
+
+	next := newStatus()
+	next.PodState["cilium"] = PodStateCount{Type: "DaemonSet", Desired: 3, Ready: 3, Available: 3}
+
+	diff := next.Diff(prev)
+
+	if len(diff.PodState) != 0 || len(diff.Images) != 0 || len(diff.Subsystems) != 0 {
+		t.Errorf("expected an empty diff, got %+v", diff)
+	}
+	if diff.Regressed() {
+		t.Errorf("expected an identical snapshot to not be a regression")
+	}
+}
+
+func TestStatusDiffPersistentControllerFailureNotRegressed(t *testing.T) {
+	// Same controller, same deployment, only the reported failure duration
+	// changed between polls. Since both messages are tagged with the fixed
+	// "Controller" subsystem, this must not show up as a new error and a
+	// cleared error on every comparison.
+	prev := newStatus()
+	prev.AddAggregatedError("cilium", "cilium-aaa", fmt.Errorf("Controller: sync-policymap is failing since 1m0s (3x): boom"))
+
+	next := newStatus()
+	next.AddAggregatedError("cilium", "cilium-aaa", fmt.Errorf("Controller: sync-policymap is failing since 1m10s (4x): boom"))
+
+	diff := next.Diff(prev)
+
+	if sub, ok := diff.Subsystems["cilium"]; ok {
+		if len(sub.NewErrors) != 0 || len(sub.ClearedErrors) != 0 {
+			t.Errorf("expected no subsystem churn for a persistently-failing controller, got %+v", sub)
+		}
+	}
+	if diff.Regressed() {
+		t.Errorf("expected a persistently-failing controller to not count as a new regression")
+	}
+}
+
+func TestStatusDiffDisappearedDeployment(t *testing.T) {
+	prev := newStatus()
+	prev.PodState["clustermesh-apiserver"] = PodStateCount{Type: "Deployment", Desired: 1, Ready: 1, Available: 1}
+
+	next := newStatus()
+
+	diff := next.Diff(prev)
+
+	if len(diff.Disappeared) != 1 || diff.Disappeared[0] != "clustermesh-apiserver" {
+		t.Errorf("expected clustermesh-apiserver to be disappeared, got %v", diff.Disappeared)
+	}
+	if !diff.Regressed() {
+		t.Errorf("expected a disappeared deployment to count as a regression")
+	}
+}
@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package status
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+// gatewayAPIDeploymentName is the key GatewayAPI status is recorded under,
+// mirroring the defaults.*DeploymentName/*DaemonSetName constants used for
+// the other rows of Format(). Gateway API isn't deployed as its own
+// workload, so it has no corresponding defaults constant.
+const gatewayAPIDeploymentName = "gateway-api"
+
+// gatewayControllerName is the controller name Cilium's Gateway API
+// implementation registers its GatewayClasses under.
+const gatewayControllerName = "io.cilium/gateway-controller"
+
+// GatewayAPIStatus summarizes Cilium's Gateway API integration: whether the
+// CRDs are installed, and how many Gateways/HTTPRoutes are managed by a
+// GatewayClass controlled by Cilium.
+type GatewayAPIStatus struct {
+	// Installed is false if the Gateway API CRDs are not present in the
+	// cluster.
+	Installed bool `json:"installed"`
+
+	// Gateways is the number of Gateways using a Cilium-controlled
+	// GatewayClass.
+	Gateways int `json:"gateways"`
+
+	// HTTPRoutes is the number of HTTPRoutes attached to those Gateways.
+	HTTPRoutes int `json:"http_routes"`
+}
+
+// parseGatewayAPIStatus inspects the installed GatewayClasses, Gateways and
+// HTTPRoutes and folds their Accepted/Programmed/ResolvedRefs conditions
+// into s's aggregated errors and warnings, the same way parseStatusResponse
+// does for Cilium's own subsystems.
+func (s *Status) parseGatewayAPIStatus(crdsInstalled bool, classes []gatewayv1.GatewayClass, gateways []gatewayv1.Gateway, routes []gatewayv1.HTTPRoute) {
+	s.SetDisabled(gatewayAPIDeploymentName, gatewayAPIDeploymentName, !crdsInstalled)
+	if !crdsInstalled {
+		return
+	}
+
+	ciliumClasses := map[string]bool{}
+	for _, class := range classes {
+		if string(class.Spec.ControllerName) == gatewayControllerName {
+			ciliumClasses[class.Name] = true
+		}
+	}
+
+	// managedGateways is keyed by "namespace/name" so that Gateways sharing
+	// a name across namespaces aren't conflated when matching HTTPRoutes
+	// below.
+	managedGateways := map[string]bool{}
+
+	var gw GatewayAPIStatus
+	gw.Installed = true
+
+	for _, gateway := range gateways {
+		if !ciliumClasses[string(gateway.Spec.GatewayClassName)] {
+			continue
+		}
+
+		managedGateways[gateway.Namespace+"/"+gateway.Name] = true
+		gw.Gateways++
+		checkGatewayAPIConditions(s, gateway.Name, gateway.Status.Conditions)
+	}
+
+	for _, route := range routes {
+		managed := false
+		for _, ref := range route.Spec.ParentRefs {
+			if ref.Name == "" {
+				continue
+			}
+
+			// ParentReference.Namespace defaults to the route's own
+			// namespace when unset, per the Gateway API spec.
+			namespace := route.Namespace
+			if ref.Namespace != nil && *ref.Namespace != "" {
+				namespace = string(*ref.Namespace)
+			}
+
+			if managedGateways[namespace+"/"+string(ref.Name)] {
+				managed = true
+			}
+		}
+		if !managed {
+			continue
+		}
+
+		gw.HTTPRoutes++
+		for _, parent := range route.Status.Parents {
+			checkGatewayAPIConditions(s, route.Name, parent.Conditions)
+		}
+	}
+
+	s.GatewayAPI = &gw
+}
+
+// checkGatewayAPIConditions records an aggregated warning or error for each
+// Accepted/Programmed/ResolvedRefs condition that isn't in its expected
+// "True" state.
+func checkGatewayAPIConditions(s *Status, name string, conditions []metav1.Condition) {
+	for _, cond := range conditions {
+		switch cond.Type {
+		case "Accepted", "Programmed", "ResolvedRefs":
+		default:
+			continue
+		}
+
+		if cond.Status == metav1.ConditionTrue {
+			continue
+		}
+
+		msg := fmt.Errorf("%s: %s", cond.Type, cond.Message)
+		if cond.Status == metav1.ConditionUnknown {
+			s.AddAggregatedWarning(gatewayAPIDeploymentName, name, msg)
+		} else {
+			s.AddAggregatedError(gatewayAPIDeploymentName, name, msg)
+		}
+	}
+}
+
+// gatewayAPIStatusSummary renders the Gateway API row of Format(), which
+// unlike statusSummary() reports resource counts instead of a plain OK when
+// everything is healthy.
+func (s *Status) gatewayAPIStatusSummary() string {
+	if s.GatewayAPI == nil || !s.GatewayAPI.Installed {
+		return Cyan + "disabled (CRDs not installed)" + Reset
+	}
+
+	base := fmt.Sprintf("%d gateways, %d routes", s.GatewayAPI.Gateways, s.GatewayAPI.HTTPRoutes)
+
+	if text := s.statusSummary(gatewayAPIDeploymentName); !strings.HasPrefix(text, Green) {
+		return base + ", " + text
+	}
+
+	return base
+}
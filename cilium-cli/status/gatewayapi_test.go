@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package status
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+)
+
+func namespacePtr(ns string) *gatewayv1.Namespace {
+	n := gatewayv1.Namespace(ns)
+	return &n
+}
+
+func TestParseGatewayAPIStatusMatchesAcrossNamespaces(t *testing.T) {
+	classes := []gatewayv1.GatewayClass{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cilium"},
+			Spec:       gatewayv1.GatewayClassSpec{ControllerName: gatewayControllerName},
+		},
+	}
+
+	// Two Gateways share the name "external" in different namespaces; only
+	// the one in "team-a" should be matched by the HTTPRoutes below.
+	gateways := []gatewayv1.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "team-a"},
+			Spec:       gatewayv1.GatewaySpec{GatewayClassName: "cilium"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "team-b"},
+			Spec:       gatewayv1.GatewaySpec{GatewayClassName: "cilium"},
+		},
+	}
+
+	routes := []gatewayv1.HTTPRoute{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "route-with-namespace", Namespace: "team-a"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{
+						{Name: "external", Namespace: namespacePtr("team-a")},
+					},
+				},
+			},
+		},
+		{
+			// ParentRef omits Namespace, so it must default to the route's
+			// own namespace ("team-a"), not match "team-b"'s Gateway.
+			ObjectMeta: metav1.ObjectMeta{Name: "route-defaulted-namespace", Namespace: "team-a"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{
+						{Name: "external"},
+					},
+				},
+			},
+		},
+		{
+			// Same Gateway name but in "team-c", which has no Gateway of that
+			// name, so it must not be matched against team-a's Gateway.
+			ObjectMeta: metav1.ObjectMeta{Name: "route-other-namespace", Namespace: "team-c"},
+			Spec: gatewayv1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1.CommonRouteSpec{
+					ParentRefs: []gatewayv1.ParentReference{
+						{Name: "external"},
+					},
+				},
+			},
+		},
+	}
+
+	s := newStatus()
+	s.parseGatewayAPIStatus(true, classes, gateways, routes)
+
+	if s.GatewayAPI == nil {
+		t.Fatalf("expected GatewayAPI status to be set")
+	}
+	if s.GatewayAPI.Gateways != 2 {
+		t.Errorf("expected 2 managed gateways, got %d", s.GatewayAPI.Gateways)
+	}
+	if s.GatewayAPI.HTTPRoutes != 2 {
+		t.Errorf("expected 2 matched routes (team-a only), got %d", s.GatewayAPI.HTTPRoutes)
+	}
+}
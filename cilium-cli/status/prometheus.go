@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package status
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cilium/cilium/api/v1/models"
+	"github.com/cilium/cilium/cilium-cli/defaults"
+)
+
+// subsystems lists the Cilium subsystems reported via
+// parseCiliumSubsystemState/parseCiliumSubsystemStatus, along with the
+// deployment they're reported against and the prefix their aggregated
+// errors/warnings are recorded under, so that cilium_status_subsystem_up
+// can be derived from the same data Format() already renders.
+var subsystems = []struct {
+	name       string
+	deployment string
+	prefix     string
+}{
+	{"cilium", defaults.AgentDaemonSetName, "Cilium"},
+	{"hubble", defaults.AgentDaemonSetName, "Hubble"},
+	{"kvstore", defaults.AgentDaemonSetName, "Kvstore"},
+	{"kubernetes", defaults.AgentDaemonSetName, "Kubernetes"},
+	{"auth-cert", defaults.AgentDaemonSetName, "AuthCertificateProvider"},
+}
+
+// subsystemUp reports whether deployment has no recorded error or warning
+// whose message starts with "prefix: ", which is how
+// parseCiliumSubsystemState tags per-subsystem problems.
+func (s *Status) subsystemUp(deployment, prefix string) bool {
+	for _, pod := range s.Errors[deployment] {
+		for _, err := range pod.Errors {
+			if strings.HasPrefix(err.Error(), prefix+":") {
+				return false
+			}
+		}
+		for _, warn := range pod.Warnings {
+			if strings.HasPrefix(warn.Error(), prefix+":") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FormatPrometheus renders s as Prometheus/OpenMetrics text-format metrics,
+// mirroring the data Format() prints to a terminal so the same signal can
+// be fed into alerting pipelines without shelling out to `cilium status`.
+func (s *Status) FormatPrometheus() string {
+	var buf bytes.Buffer
+
+	fmt.Fprintln(&buf, "# HELP cilium_status_pods Number of pods in a given state, by deployment.")
+	fmt.Fprintln(&buf, "# TYPE cilium_status_pods gauge")
+	for _, name := range slices.Sorted(maps.Keys(s.PodState)) {
+		p := s.PodState[name]
+		fmt.Fprintf(&buf, "cilium_status_pods{deployment=%q,state=\"desired\"} %d\n", name, p.Desired)
+		fmt.Fprintf(&buf, "cilium_status_pods{deployment=%q,state=\"ready\"} %d\n", name, p.Ready)
+		fmt.Fprintf(&buf, "cilium_status_pods{deployment=%q,state=\"available\"} %d\n", name, p.Available)
+		fmt.Fprintf(&buf, "cilium_status_pods{deployment=%q,state=\"unavailable\"} %d\n", name, p.Unavailable)
+	}
+
+	fmt.Fprintln(&buf, "# HELP cilium_status_container_phase Number of containers in a given phase, by deployment.")
+	fmt.Fprintln(&buf, "# TYPE cilium_status_container_phase gauge")
+	for _, name := range slices.Sorted(maps.Keys(s.PhaseCount)) {
+		for _, phase := range slices.Sorted(maps.Keys(s.PhaseCount[name])) {
+			fmt.Fprintf(&buf, "cilium_status_container_phase{deployment=%q,phase=%q} %d\n", name, phase, s.PhaseCount[name][phase])
+		}
+	}
+
+	fmt.Fprintln(&buf, "# HELP cilium_status_image_in_use Number of pods running a given image, by deployment.")
+	fmt.Fprintln(&buf, "# TYPE cilium_status_image_in_use gauge")
+	for _, name := range slices.Sorted(maps.Keys(s.ImageCount)) {
+		for _, image := range slices.Sorted(maps.Keys(s.ImageCount[name])) {
+			fmt.Fprintf(&buf, "cilium_status_image_in_use{deployment=%q,image=%q} %d\n", name, image, s.ImageCount[name][image])
+		}
+	}
+
+	fmt.Fprintln(&buf, "# HELP cilium_status_errors_total Total number of errors observed, by deployment, pod and subsystem.")
+	fmt.Fprintln(&buf, "# TYPE cilium_status_errors_total counter")
+	for _, deployment := range slices.Sorted(maps.Keys(s.Errors)) {
+		pods := s.Errors[deployment]
+		for _, pod := range slices.Sorted(maps.Keys(pods)) {
+			for subsystem, count := range countBySubsystem(pods[pod].Errors) {
+				fmt.Fprintf(&buf, "cilium_status_errors_total{deployment=%q,pod=%q,subsystem=%q} %d\n", deployment, pod, subsystem, count)
+			}
+		}
+	}
+
+	fmt.Fprintln(&buf, "# HELP cilium_status_warnings_total Total number of warnings observed, by deployment, pod and subsystem.")
+	fmt.Fprintln(&buf, "# TYPE cilium_status_warnings_total counter")
+	for _, deployment := range slices.Sorted(maps.Keys(s.Errors)) {
+		pods := s.Errors[deployment]
+		for _, pod := range slices.Sorted(maps.Keys(pods)) {
+			for subsystem, count := range countBySubsystem(pods[pod].Warnings) {
+				fmt.Fprintf(&buf, "cilium_status_warnings_total{deployment=%q,pod=%q,subsystem=%q} %d\n", deployment, pod, subsystem, count)
+			}
+		}
+	}
+
+	fmt.Fprintln(&buf, "# HELP cilium_status_endpoints_not_ready Number of Cilium endpoints that are not in the ready state, by pod.")
+	fmt.Fprintln(&buf, "# TYPE cilium_status_endpoints_not_ready gauge")
+	for pod, eps := range s.CiliumEndpoints {
+		var notReady int
+		for _, ep := range eps {
+			if ep != nil && ep.Status != nil && ep.Status.State != nil && *ep.Status.State != models.EndpointStateReady {
+				notReady++
+			}
+		}
+		fmt.Fprintf(&buf, "cilium_status_endpoints_not_ready{pod=%q} %d\n", pod, notReady)
+	}
+
+	fmt.Fprintln(&buf, "# HELP cilium_status_subsystem_up Whether a Cilium subsystem is free of reported errors or warnings.")
+	fmt.Fprintln(&buf, "# TYPE cilium_status_subsystem_up gauge")
+	for _, sub := range subsystems {
+		up := 0
+		if s.subsystemUp(sub.deployment, sub.prefix) {
+			up = 1
+		}
+		fmt.Fprintf(&buf, "cilium_status_subsystem_up{subsystem=%q} %d\n", sub.name, up)
+	}
+
+	return buf.String()
+}
+
+// countBySubsystem tallies errs by the subsystem prefix parseCiliumSubsystemState
+// tags each message with (e.g. "Kvstore: ...").
+func countBySubsystem(errs []error) map[string]int {
+	counts := map[string]int{}
+	for _, err := range errs {
+		subsystem, _, found := strings.Cut(err.Error(), ":")
+		if !found {
+			subsystem = "unknown"
+		}
+		counts[subsystem]++
+	}
+	return counts
+}
+
+// Serve exposes a /metrics handler that renders collect's latest Status in
+// Prometheus text format, backing the `--serve` flag so scrapers can pull
+// cilium-cli status without shelling out.
+func Serve(ctx context.Context, addr string, collect func(ctx context.Context) (*Status, error)) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		s, err := collect(r.Context())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to collect status: %s", err), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		fmt.Fprint(w, s.FormatPrometheus())
+	})
+
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("unable to serve metrics: %w", err)
+	}
+	return nil
+}
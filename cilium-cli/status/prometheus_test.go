@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package status
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cilium/cilium/api/v1/models"
+)
+
+func TestFormatPrometheusEndpointsNotReady(t *testing.T) {
+	notReady := models.EndpointState("waiting-for-identity")
+
+	s := newStatus()
+	s.CiliumEndpoints["cilium-xyz"] = []*models.Endpoint{
+		{Status: &models.EndpointStatus{State: &notReady}},
+		{Status: &models.EndpointStatus{State: &notReady}},
+	}
+
+	out := s.FormatPrometheus()
+
+	want := `cilium_status_endpoints_not_ready{pod="cilium-xyz"} 2`
+	if !strings.Contains(out, want) {
+		t.Errorf("expected output to contain %q, got:\n%s", want, out)
+	}
+}
+
+func TestCountBySubsystemControllerFailure(t *testing.T) {
+	// The failure duration in these messages (3m2s vs 3m9s) changes on
+	// every poll; countBySubsystem must still group both under the fixed
+	// "Controller" label rather than under the duration itself.
+	errs := []error{
+		fmt.Errorf("Controller: controller-a is failing since 3m2s (5x): boom"),
+		fmt.Errorf("Controller: controller-a is failing since 3m9s (6x): boom"),
+	}
+
+	counts := countBySubsystem(errs)
+
+	if counts["Controller"] != 2 {
+		t.Errorf("expected 2 errors grouped under the Controller subsystem, got %+v", counts)
+	}
+	if _, ok := counts["3m2s"]; ok {
+		t.Errorf("expected the failure duration to not leak into the subsystem label, got %+v", counts)
+	}
+}
@@ -29,8 +29,10 @@ const (
 )
 
 const (
-	OutputJSON    = "json"
-	OutputSummary = "summary"
+	OutputJSON       = "json"
+	OutputSummary    = "summary"
+	OutputJSONStream = "jsonstream"
+	OutputPrometheus = "prometheus"
 )
 
 // MapCount is a map to count number of occurrences of a string
@@ -128,6 +130,10 @@ type Status struct {
 	// by each Cilium agent.
 	CiliumEndpoints CiliumEndpointsMap `json:"cilium_endpoints,omitempty"`
 
+	// GatewayAPI is the status of Cilium's Gateway API integration, or nil
+	// if the Gateway API CRDs are not installed.
+	GatewayAPI *GatewayAPIStatus `json:"gateway_api,omitempty"`
+
 	// Errors is the aggregated errors and warnings of all pods of a
 	// particular deployment type
 	Errors ErrorCountMapMap `json:"errors,omitempty"`
@@ -260,7 +266,12 @@ func (s *Status) parseStatusResponse(deployment, podName string, r *models.Statu
 				continue
 			}
 
-			s.AddAggregatedError(deployment, podName, fmt.Errorf("controller %s is failing since %s (%dx): %s",
+			// Tag every controller failure with the fixed "Controller"
+			// subsystem, not the controller name or failure duration, so
+			// countBySubsystem and subsystemSet (which group on the text
+			// before the first ":") get a stable label instead of one that
+			// changes on every poll.
+			s.AddAggregatedError(deployment, podName, fmt.Errorf("Controller: %s is failing since %s (%dx): %s",
 				ctrl.Name,
 				time.Since(time.Time(ctrl.Status.LastFailureTimestamp)).Truncate(time.Second).String(),
 				ctrl.Status.ConsecutiveFailureCount,
@@ -388,6 +399,7 @@ func (s *Status) Format() string {
 	fmt.Fprint(w, Green+" /¯¯"+Red+"\\__/"+Magenta+"¯¯\\"+Reset+"\tEnvoy DaemonSet:\t"+envoyStatusSummary(s.statusSummary(defaults.EnvoyDaemonSetName))+"\n")
 	fmt.Fprint(w, Green+" \\__"+Blue+"/¯¯\\"+Magenta+"__/"+Reset+"\tHubble Relay:\t"+s.statusSummary(defaults.RelayDeploymentName)+"\n")
 	fmt.Fprint(w, Blue+Blue+Blue+"    \\__/"+Reset+"\tClusterMesh:\t"+s.statusSummary(defaults.ClusterMeshDeploymentName)+"\n")
+	fmt.Fprintf(w, "\tGateway API:\t%s\n", s.gatewayAPIStatusSummary())
 	fmt.Fprint(w, "\n")
 
 	for _, name := range slices.Sorted(maps.Keys(s.PodState)) {
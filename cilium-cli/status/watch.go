@@ -0,0 +1,435 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright Authors of Cilium
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/cilium/cilium/api/v1/models"
+	ciliumv2 "github.com/cilium/cilium/pkg/k8s/apis/cilium.io/v2"
+	ciliumClientset "github.com/cilium/cilium/pkg/k8s/client/clientset/versioned"
+	"github.com/cilium/cilium/pkg/lock"
+)
+
+// K8sClient is the subset of client-go and Cilium clientset access that
+// Watch needs in order to set up informers. It is satisfied by the k8s
+// client used elsewhere in cilium-cli.
+type K8sClient interface {
+	Clientset() kubernetes.Interface
+	CiliumClientset() ciliumClientset.Interface
+	Namespace() string
+}
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	// MinResyncInterval is the minimum time between two re-issued
+	// snapshots, used to coalesce bursts of informer events (e.g. a
+	// rollout touching every pod of a DaemonSet) into a single refresh.
+	MinResyncInterval time.Duration
+}
+
+// podState is the subset of a pod's state that determines whether the
+// Cilium API needs to be queried again for it.
+type podState struct {
+	generation int64
+	ready      bool
+	nodeIP     string
+}
+
+// Watch continuously recomputes Status as Pods, DaemonSets, Deployments and
+// CiliumEndpoints change in the cluster, pushing a new snapshot on the
+// returned channel whenever something relevant changed. Rather than polling
+// every pod with parseStatusResponse/parseEndpointsResponse on a timer,
+// Watch is backed by shared informers: the Cilium API is only called again
+// for new pods and pods whose generation, ready-state or host IP changed.
+//
+// The returned channel is closed once ctx is done.
+func Watch(ctx context.Context, client K8sClient, collect func(ctx context.Context, deployment, pod string) (*models.StatusResponse, []*models.Endpoint, error), opts WatchOptions) (<-chan *Status, error) {
+	if opts.MinResyncInterval == 0 {
+		opts.MinResyncInterval = time.Second
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(client.Clientset(), 0,
+		informers.WithNamespace(client.Namespace()))
+
+	podInformer := factory.Core().V1().Pods().Informer()
+	dsInformer := factory.Apps().V1().DaemonSets().Informer()
+	depInformer := factory.Apps().V1().Deployments().Informer()
+
+	w := &watcher{
+		client:    client,
+		collect:   collect,
+		seen:      map[string]podState{},
+		dirtyPods: map[string]struct{}{},
+		results:   map[string]podResult{},
+		dirty:     make(chan struct{}, 1),
+		out:       make(chan *Status),
+	}
+
+	if _, err := podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { w.onPod(obj) },
+		UpdateFunc: func(_, obj any) { w.onPod(obj) },
+		DeleteFunc: func(obj any) { w.onPodDelete(obj) },
+	}); err != nil {
+		return nil, fmt.Errorf("unable to watch pods: %w", err)
+	}
+
+	markDirtyHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { w.markDirty() },
+		UpdateFunc: func(_, any) { w.markDirty() },
+		DeleteFunc: func(any) { w.markDirty() },
+	}
+	if _, err := dsInformer.AddEventHandler(markDirtyHandler); err != nil {
+		return nil, fmt.Errorf("unable to watch daemonsets: %w", err)
+	}
+	if _, err := depInformer.AddEventHandler(markDirtyHandler); err != nil {
+		return nil, fmt.Errorf("unable to watch deployments: %w", err)
+	}
+
+	ciliumEndpointInformer, err := newCiliumEndpointInformer(client, markDirtyHandler)
+	if err != nil {
+		return nil, err
+	}
+
+	factory.Start(ctx.Done())
+	go ciliumEndpointInformer.Run(ctx.Done())
+	factory.WaitForCacheSync(ctx.Done())
+	cache.WaitForCacheSync(ctx.Done(), ciliumEndpointInformer.HasSynced)
+
+	go w.run(ctx, factory, opts.MinResyncInterval)
+
+	return w.out, nil
+}
+
+// newCiliumEndpointInformer sets up a shared informer over CiliumEndpoint
+// resources backed by the Cilium clientset, since they aren't covered by
+// the core/apps SharedInformerFactory used for Pods/DaemonSets/Deployments.
+func newCiliumEndpointInformer(client K8sClient, handler cache.ResourceEventHandlerFuncs) (cache.SharedIndexInformer, error) {
+	cs := client.CiliumClientset()
+	lw := &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return cs.CiliumV2().CiliumEndpoints(client.Namespace()).List(context.Background(), opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return cs.CiliumV2().CiliumEndpoints(client.Namespace()).Watch(context.Background(), opts)
+		},
+	}
+
+	informer := cache.NewSharedIndexInformer(lw, &ciliumv2.CiliumEndpoint{}, 0, cache.Indexers{})
+	if _, err := informer.AddEventHandler(handler); err != nil {
+		return nil, fmt.Errorf("unable to watch cilium endpoints: %w", err)
+	}
+
+	return informer, nil
+}
+
+// podResult is the last Cilium API response collected for a pod, cached so
+// that reconcile can carry it forward for pods that aren't dirty.
+type podResult struct {
+	status *models.StatusResponse
+	eps    []*models.Endpoint
+	err    error
+}
+
+type watcher struct {
+	client  K8sClient
+	collect func(ctx context.Context, deployment, pod string) (*models.StatusResponse, []*models.Endpoint, error)
+
+	mu        lock.Mutex
+	seen      map[string]podState
+	dirtyPods map[string]struct{}
+	results   map[string]podResult
+
+	dirty chan struct{}
+	out   chan *Status
+}
+
+func (w *watcher) markDirty() {
+	select {
+	case w.dirty <- struct{}{}:
+	default:
+	}
+}
+
+// onPod records the pod's generation, ready-state and host IP, and only flags
+// it for re-collection - and marks the snapshot dirty - if any of them changed
+// since the last time it was observed.
+func (w *watcher) onPod(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+
+	next := podState{
+		generation: pod.Generation,
+		ready:      isPodReady(pod),
+		nodeIP:     pod.Status.HostIP,
+	}
+
+	w.mu.Lock()
+	prev, existed := w.seen[pod.Name]
+	w.seen[pod.Name] = next
+	changed := !existed || prev != next
+	if changed {
+		w.dirtyPods[pod.Name] = struct{}{}
+	}
+	w.mu.Unlock()
+
+	if changed {
+		w.markDirty()
+	}
+}
+
+// onPodDelete drops a deleted pod's cached generation/ready-state and
+// collected result, and marks the snapshot dirty so its PodState counts get
+// recomputed.
+func (w *watcher) onPodDelete(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		tombstone, isTombstone := obj.(cache.DeletedFinalStateUnknown)
+		if !isTombstone {
+			w.markDirty()
+			return
+		}
+
+		pod, ok = tombstone.Obj.(*corev1.Pod)
+		if !ok {
+			w.markDirty()
+			return
+		}
+	}
+
+	w.mu.Lock()
+	delete(w.seen, pod.Name)
+	delete(w.dirtyPods, pod.Name)
+	delete(w.results, pod.Name)
+	w.mu.Unlock()
+
+	w.markDirty()
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// run reconciles a fresh Status every time the dirty channel fires,
+// coalescing bursts of events that land within minResync of each other,
+// and publishes the result on w.out.
+func (w *watcher) run(ctx context.Context, factory informers.SharedInformerFactory, minResync time.Duration) {
+	defer close(w.out)
+
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	pending := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.dirty:
+			if !pending {
+				pending = true
+				timer.Reset(minResync)
+			}
+		case <-timer.C:
+			pending = false
+			s, err := w.reconcile(ctx, factory)
+			if err != nil {
+				s = newStatus()
+				s.CollectionError(err)
+			}
+			select {
+			case w.out <- s:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// reconcile walks the informer caches for the watched Pods, DaemonSets and
+// Deployments, recomputing PodStateCount/PhaseCount/ImageCount/PodsCount.
+// The Cilium API is only re-queried for pods flagged dirty since the
+// previous reconciliation (new pods included); every other pod's last
+// collected result is carried forward from w.results.
+func (w *watcher) reconcile(ctx context.Context, factory informers.SharedInformerFactory) (*Status, error) {
+	s := newStatus()
+
+	pods, err := factory.Core().V1().Pods().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods: %w", err)
+	}
+
+	w.mu.Lock()
+	dirty := w.dirtyPods
+	w.dirtyPods = map[string]struct{}{}
+	w.mu.Unlock()
+
+	s.PodsCount.All = len(pods)
+
+	for _, pod := range pods {
+		deployment, ok := deploymentForPod(pod)
+		if !ok {
+			continue
+		}
+
+		s.PodsCount.ByCilium++
+		countPodPhase(s, deployment, pod)
+		countPodImages(s, deployment, pod)
+
+		w.mu.Lock()
+		result, cached := w.results[pod.Name]
+		w.mu.Unlock()
+
+		_, isDirty := dirty[pod.Name]
+		if isDirty || !cached {
+			r, eps, err := w.collect(ctx, deployment, pod.Name)
+			result = podResult{status: r, eps: eps, err: err}
+
+			w.mu.Lock()
+			w.results[pod.Name] = result
+			w.mu.Unlock()
+		}
+
+		s.parseStatusResponse(deployment, pod.Name, result.status, result.err)
+		s.parseEndpointsResponse(deployment, pod.Name, result.eps, result.err)
+		s.CiliumEndpoints[pod.Name] = result.eps
+	}
+
+	dss, err := factory.Apps().V1().DaemonSets().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list daemonsets: %w", err)
+	}
+	for _, ds := range dss {
+		s.PodState[ds.Name] = daemonSetPodState(ds)
+	}
+
+	deps, err := factory.Apps().V1().Deployments().Lister().List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list deployments: %w", err)
+	}
+	for _, dep := range deps {
+		s.PodState[dep.Name] = deploymentPodState(dep)
+	}
+
+	return s, nil
+}
+
+// countPodPhase tallies pod into s.PhaseCount[deployment], the same field
+// Format() renders as the "Containers:" section.
+func countPodPhase(s *Status, deployment string, pod *corev1.Pod) {
+	if s.PhaseCount[deployment] == nil {
+		s.PhaseCount[deployment] = MapCount{}
+	}
+	s.PhaseCount[deployment][string(pod.Status.Phase)]++
+}
+
+// countPodImages tallies the images of pod's containers into
+// s.ImageCount[deployment], the same field Format() renders as the "Image
+// versions" section.
+func countPodImages(s *Status, deployment string, pod *corev1.Pod) {
+	if s.ImageCount[deployment] == nil {
+		s.ImageCount[deployment] = MapCount{}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		s.ImageCount[deployment][cs.Image]++
+	}
+}
+
+func daemonSetPodState(ds *appsv1.DaemonSet) PodStateCount {
+	return PodStateCount{
+		Type:        "DaemonSet",
+		Desired:     int(ds.Status.DesiredNumberScheduled),
+		Ready:       int(ds.Status.NumberReady),
+		Available:   int(ds.Status.NumberAvailable),
+		Unavailable: int(ds.Status.NumberUnavailable),
+	}
+}
+
+func deploymentPodState(dep *appsv1.Deployment) PodStateCount {
+	desired := int32(1)
+	if dep.Spec.Replicas != nil {
+		desired = *dep.Spec.Replicas
+	}
+
+	return PodStateCount{
+		Type:        "Deployment",
+		Desired:     int(desired),
+		Ready:       int(dep.Status.ReadyReplicas),
+		Available:   int(dep.Status.AvailableReplicas),
+		Unavailable: int(dep.Status.UnavailableReplicas),
+	}
+}
+
+// deploymentForPod maps a pod to the deployment/daemonset name it belongs
+// to based on well-known owner labels (k8s-app, then app.kubernetes.io/name);
+// reconcile keys ImageCount, PhaseCount and the pod's errors by that name.
+func deploymentForPod(pod *corev1.Pod) (string, bool) {
+	if name, ok := pod.Labels["k8s-app"]; ok {
+		return name, true
+	}
+	if name, ok := pod.Labels["app.kubernetes.io/name"]; ok {
+		return name, true
+	}
+	return "", false
+}
+
+// cursorUp returns the ANSI escape sequence to move the cursor up n lines
+// and clear them, so that FormatWatch can redraw the summary in place
+// between events instead of scrolling the terminal.
+func cursorUp(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("\033[%dA\033[J", n)
+}
+
+// FormatWatch renders s the same way Format does, but first emits an ANSI
+// escape sequence that erases the previously rendered snapshot so that
+// `cilium status --watch` redraws in place instead of scrolling.
+func (s *Status) FormatWatch(prevLines int) (string, int) {
+	rendered := s.Format()
+	lines := 0
+	for _, r := range rendered {
+		if r == '\n' {
+			lines++
+		}
+	}
+
+	return cursorUp(prevLines) + rendered, lines
+}
+
+// WriteJSONStream marshals every Status received on ch as a single line of
+// JSON, for pipe-based consumers of `cilium status --watch
+// --output=jsonstream`.
+func WriteJSONStream(w io.Writer, ch <-chan *Status) error {
+	enc := json.NewEncoder(w)
+	for s := range ch {
+		if err := enc.Encode(s); err != nil {
+			return fmt.Errorf("unable to encode status: %w", err)
+		}
+	}
+	return nil
+}